@@ -0,0 +1,142 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hasHealthyInstanceFn과 getRepresentativeEntryFn은 Redis를 직접 두드리는 구현을 가리키는 변수다.
+// 테스트에서는 Redis 없이 의존성 해석 로직을 검증할 수 있도록 이 변수들을 교체한다.
+var (
+	hasHealthyInstanceFn     = hasHealthyInstance
+	getRepresentativeEntryFn = getRepresentativeEntry
+)
+
+// dependenciesReady는 주어진 의존 서비스들이 각각 healthy 인스턴스를 하나 이상 가지고 있는지 확인한다.
+func dependenciesReady(required []string) bool {
+	for _, dep := range required {
+		if !hasHealthyInstanceFn(dep) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveInitialStatus는 등록 시점에 의존 서비스 충족 여부에 따라 초기 상태를 결정한다.
+func resolveInitialStatus(required []string) string {
+	if len(required) == 0 || dependenciesReady(required) {
+		return StatusHealthy
+	}
+	return StatusPending
+}
+
+// dependencyNode는 /services/:name/dependencies 응답에 쓰이는 의존성 그래프 노드.
+type dependencyNode struct {
+	Name         string            `json:"name"`
+	Status       string            `json:"status"`
+	Dependencies []*dependencyNode `json:"dependencies,omitempty"`
+}
+
+// resolveDependencyGraph는 서비스의 의존 서비스를 재귀적으로 풀어 트리 형태로 반환한다.
+// onPath는 현재 재귀 경로에 있는 서비스 집합으로, 실제 순환만 "cycle"로 표시하는 데 쓰인다.
+// resolved는 이미 완전히 풀어낸 노드의 메모로, 다이아몬드 형태로 공유되는 의존성을
+// 가지마다 다시 순회하지 않도록 한다.
+func resolveDependencyGraph(name string, onPath map[string]bool, resolved map[string]*dependencyNode) *dependencyNode {
+	if node, ok := resolved[name]; ok {
+		return node
+	}
+	if onPath[name] {
+		return &dependencyNode{Name: name, Status: "cycle"}
+	}
+	onPath[name] = true
+	defer delete(onPath, name)
+
+	entry, err := getRepresentativeEntryFn(name)
+	if err != nil || entry == nil {
+		node := &dependencyNode{Name: name, Status: "missing"}
+		resolved[name] = node
+		return node
+	}
+
+	node := &dependencyNode{Name: name, Status: entry.Status}
+	for _, dep := range entry.RequiredServices {
+		node.Dependencies = append(node.Dependencies, resolveDependencyGraph(dep, onPath, resolved))
+	}
+	resolved[name] = node
+	return node
+}
+
+// transitivelyReady는 서비스와 그 모든 의존 서비스가 재귀적으로 healthy 상태인지 확인한다.
+// onPath는 현재 재귀 경로를 추적해 진짜 순환만 not-ready로 취급하고, ready는 이미 확인된
+// 서비스를 기억해 다이아몬드 형태로 공유되는 의존성을 가지마다 다시 순회하지 않도록 한다.
+func transitivelyReady(name string, onPath map[string]bool, ready map[string]bool) bool {
+	if ready[name] {
+		return true
+	}
+	if onPath[name] {
+		// 의존성 사이클: resolveDependencyGraph가 "cycle"로 표시하는 것과 동일하게 not-ready로 취급한다.
+		return false
+	}
+	onPath[name] = true
+	defer delete(onPath, name)
+
+	if !hasHealthyInstanceFn(name) {
+		return false
+	}
+
+	entry, err := getRepresentativeEntryFn(name)
+	if err != nil || entry == nil {
+		return false
+	}
+
+	for _, dep := range entry.RequiredServices {
+		if !transitivelyReady(dep, onPath, ready) {
+			return false
+		}
+	}
+
+	ready[name] = true
+	return true
+}
+
+// getServiceDependencies 함수: 서비스의 의존성 그래프를 반환한다.
+func getServiceDependencies(c *gin.Context) {
+	name := c.Param("name")
+
+	entry, err := getRepresentativeEntry(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get service"})
+		return
+	}
+	if entry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	graph := resolveDependencyGraph(name, make(map[string]bool), make(map[string]*dependencyNode))
+	c.JSON(http.StatusOK, graph)
+}
+
+// getServiceReadiness 함수: 서비스와 의존 서비스들이 모두 healthy인지 확인해 200/503을 반환한다.
+func getServiceReadiness(c *gin.Context) {
+	name := c.Param("name")
+
+	entry, err := getRepresentativeEntry(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get service"})
+		return
+	}
+	if entry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	ready := transitivelyReady(name, make(map[string]bool), make(map[string]bool))
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"name": name, "ready": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"name": name, "ready": true})
+}
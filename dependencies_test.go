@@ -0,0 +1,128 @@
+package main
+
+import (
+	"testing"
+
+	lib "github.com/assist-by/autro-library"
+)
+
+// withFakeDependencyLookup은 hasHealthyInstanceFn/getRepresentativeEntryFn을 Redis 없이
+// 동작하는 고정 그래프로 교체하고, 테스트가 끝나면 원래 구현으로 되돌린다.
+func withFakeDependencyLookup(t *testing.T, entries map[string]*registryEntry) {
+	t.Helper()
+
+	origHasHealthy := hasHealthyInstanceFn
+	origGetEntry := getRepresentativeEntryFn
+
+	hasHealthyInstanceFn = func(name string) bool {
+		entry, ok := entries[name]
+		return ok && entry.Status == StatusHealthy
+	}
+	getRepresentativeEntryFn = func(name string) (*registryEntry, error) {
+		entry, ok := entries[name]
+		if !ok {
+			return nil, nil
+		}
+		return entry, nil
+	}
+
+	t.Cleanup(func() {
+		hasHealthyInstanceFn = origHasHealthy
+		getRepresentativeEntryFn = origGetEntry
+	})
+}
+
+func entry(name, status string, required ...string) *registryEntry {
+	return &registryEntry{
+		Service:          lib.Service{Name: name},
+		Status:           status,
+		RequiredServices: required,
+	}
+}
+
+func TestDependenciesReady(t *testing.T) {
+	withFakeDependencyLookup(t, map[string]*registryEntry{
+		"healthy-dep":   entry("healthy-dep", StatusHealthy),
+		"unhealthy-dep": entry("unhealthy-dep", StatusUnhealthy),
+	})
+
+	if !dependenciesReady([]string{"healthy-dep"}) {
+		t.Errorf("expected a single healthy dependency to be ready")
+	}
+	if dependenciesReady([]string{"healthy-dep", "unhealthy-dep"}) {
+		t.Errorf("expected an unhealthy dependency to make the set not-ready")
+	}
+	if dependenciesReady([]string{"missing-dep"}) {
+		t.Errorf("expected a missing dependency to make the set not-ready")
+	}
+}
+
+func TestTransitivelyReady_Diamond(t *testing.T) {
+	// A depends on B and C, both of which depend on the shared, healthy D.
+	// This is not a cycle and should resolve ready=true.
+	withFakeDependencyLookup(t, map[string]*registryEntry{
+		"a": entry("a", StatusHealthy, "b", "c"),
+		"b": entry("b", StatusHealthy, "d"),
+		"c": entry("c", StatusHealthy, "d"),
+		"d": entry("d", StatusHealthy),
+	})
+
+	if !transitivelyReady("a", make(map[string]bool), make(map[string]bool)) {
+		t.Errorf("expected diamond-shaped shared dependency to be ready, got not-ready")
+	}
+}
+
+func TestTransitivelyReady_Cycle(t *testing.T) {
+	// a -> b -> a is a genuine cycle and must be treated as not-ready.
+	withFakeDependencyLookup(t, map[string]*registryEntry{
+		"a": entry("a", StatusHealthy, "b"),
+		"b": entry("b", StatusHealthy, "a"),
+	})
+
+	if transitivelyReady("a", make(map[string]bool), make(map[string]bool)) {
+		t.Errorf("expected a genuine dependency cycle to be not-ready")
+	}
+}
+
+func TestTransitivelyReady_UnhealthyDependency(t *testing.T) {
+	withFakeDependencyLookup(t, map[string]*registryEntry{
+		"a": entry("a", StatusHealthy, "b"),
+		"b": entry("b", StatusUnhealthy),
+	})
+
+	if transitivelyReady("a", make(map[string]bool), make(map[string]bool)) {
+		t.Errorf("expected an unhealthy transitive dependency to be not-ready")
+	}
+}
+
+func TestResolveDependencyGraph_DiamondIsNotCycle(t *testing.T) {
+	withFakeDependencyLookup(t, map[string]*registryEntry{
+		"a": entry("a", StatusHealthy, "b", "c"),
+		"b": entry("b", StatusHealthy, "d"),
+		"c": entry("c", StatusHealthy, "d"),
+		"d": entry("d", StatusHealthy),
+	})
+
+	graph := resolveDependencyGraph("a", make(map[string]bool), make(map[string]*dependencyNode))
+
+	for _, branch := range graph.Dependencies {
+		for _, leaf := range branch.Dependencies {
+			if leaf.Status == "cycle" {
+				t.Errorf("shared dependency %s mislabeled as cycle", leaf.Name)
+			}
+		}
+	}
+}
+
+func TestResolveDependencyGraph_GenuineCycle(t *testing.T) {
+	withFakeDependencyLookup(t, map[string]*registryEntry{
+		"a": entry("a", StatusHealthy, "b"),
+		"b": entry("b", StatusHealthy, "a"),
+	})
+
+	graph := resolveDependencyGraph("a", make(map[string]bool), make(map[string]*dependencyNode))
+
+	if len(graph.Dependencies) != 1 || graph.Dependencies[0].Dependencies[0].Status != "cycle" {
+		t.Errorf("expected genuine cycle back to %q to be labeled as such", "a")
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	lib "github.com/assist-by/autro-library"
+)
+
+// instanceKey는 한 서비스 인스턴스의 데이터가 저장되는 Redis 키.
+func instanceKey(name, instanceID string) string {
+	return fmt.Sprintf("service:%s:%s", name, instanceID)
+}
+
+// instancesSetKey는 한 서비스 이름에 속한 인스턴스 ID 집합의 Redis 키.
+func instancesSetKey(name string) string {
+	return fmt.Sprintf("service:%s:instances", name)
+}
+
+// roundRobinKey는 round-robin 선택 전략이 사용하는 커서 카운터의 Redis 키.
+func roundRobinKey(name string) string {
+	return fmt.Sprintf("service:%s:rr", name)
+}
+
+// computeInstanceID는 Name+Address의 해시로 인스턴스 ID를 만든다.
+// 같은 주소로 재등록되면 같은 ID가 나와 기존 인스턴스를 갱신하게 된다.
+func computeInstanceID(service lib.Service) string {
+	h := fnv.New64a()
+	h.Write([]byte(service.Name + "|" + service.Address))
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// getInstances는 주어진 서비스 이름에 속한 모든 인스턴스를 반환한다.
+// 이미 TTL로 만료된 인스턴스 ID는 집합에서 제거한다.
+func getInstances(name string) ([]registryEntry, error) {
+	ids, err := redisClient.SMembers(ctx, instancesSetKey(name)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]registryEntry, 0, len(ids))
+	for _, id := range ids {
+		entryJSON, err := redisClient.Get(ctx, instanceKey(name, id)).Result()
+		if err == redis.Nil {
+			redisClient.SRem(ctx, instancesSetKey(name), id)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		var entry registryEntry
+		if err := json.Unmarshal([]byte(entryJSON), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// getRepresentativeEntry는 의존성 해석 등에서 서비스 하나를 대표하는 인스턴스를 고른다.
+// healthy 인스턴스가 있으면 그중 하나, 없으면 첫 번째 인스턴스를 반환한다.
+func getRepresentativeEntry(name string) (*registryEntry, error) {
+	entries, err := getInstances(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	for _, entry := range entries {
+		if entry.Status == StatusHealthy {
+			return &entry, nil
+		}
+	}
+	return &entries[0], nil
+}
+
+// hasHealthyInstance는 서비스에 healthy 상태인 인스턴스가 하나라도 있는지 확인한다.
+func hasHealthyInstance(name string) bool {
+	entries, err := getInstances(name)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.Status == StatusHealthy {
+			return true
+		}
+	}
+	return false
+}
+
+// getServiceInstance 함수: GET /services/:name/instance
+// strategy 쿼리 파라미터(random|round-robin|least-heartbeat-age)로 healthy 인스턴스 하나를 선택해 반환한다.
+func getServiceInstance(c *gin.Context) {
+	name := c.Param("name")
+	strategy := c.DefaultQuery("strategy", "random")
+
+	entries, err := getInstances(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get instances"})
+		return
+	}
+
+	healthy := make([]registryEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Status == StatusHealthy {
+			healthy = append(healthy, entry)
+		}
+	}
+
+	if len(healthy) == 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No healthy instances available"})
+		return
+	}
+
+	// SMembers의 순서는 호출마다, 그리고 인스턴스가 추가/제거될 때마다 안정적이지 않으므로,
+	// round-robin 커서가 일관된 순서 위를 돌게끔 InstanceID로 정렬해 고정한다.
+	sort.Slice(healthy, func(i, j int) bool {
+		return healthy[i].InstanceID < healthy[j].InstanceID
+	})
+
+	var chosen registryEntry
+	switch strategy {
+	case "round-robin":
+		count, err := redisClient.Incr(ctx, roundRobinKey(name)).Result()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to select instance"})
+			return
+		}
+		chosen = healthy[int(count-1)%len(healthy)]
+	case "least-heartbeat-age":
+		chosen = healthy[0]
+		for _, entry := range healthy[1:] {
+			if entry.LastHeartbeat.After(chosen.LastHeartbeat) {
+				chosen = entry
+			}
+		}
+	case "random":
+		chosen = healthy[rand.Intn(len(healthy))]
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown strategy: " + strategy})
+		return
+	}
+
+	c.JSON(http.StatusOK, chosen)
+}
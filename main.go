@@ -16,11 +16,29 @@ import (
 	lib "github.com/assist-by/autro-library"
 )
 
+// 서비스 상태값
+const (
+	StatusHealthy      = "healthy"
+	StatusUnhealthy    = "unhealthy"
+	StatusPending      = "pending"
+	StatusDeregistered = "deregistered"
+)
+
+// registryEntry는 lib.Service에 레지스트리 전용 메타데이터(상태, 의존 서비스, 인스턴스 ID)를 덧붙여 Redis에 저장하는 형태.
+type registryEntry struct {
+	lib.Service
+	Status           string   `json:"status"`
+	RequiredServices []string `json:"required_services,omitempty"`
+	InstanceID       string   `json:"instance_id"`
+}
+
 var (
 	redisClient       *redis.Client
 	ctx               = context.Background()
 	kafkaBroker       string
 	registrationTopic string
+	eventsTopic       string
+	serviceTTL        time.Duration
 )
 
 func init() {
@@ -48,6 +66,39 @@ func init() {
 		registrationTopic = "service-registration"
 	}
 
+	eventsTopic = os.Getenv("REGISTRY_EVENTS_TOPIC")
+	if eventsTopic == "" {
+		eventsTopic = "registry-events"
+	}
+
+	initSweeper()
+	initQuotas()
+
+	// serviceTTL은 sweeper가 grace -> unhealthy -> deregister 상태를 모두 거칠 시간을 벌어주는
+	// 안전망이어야 한다. deregisterTimeout보다 짧으면 sweeper가 제거하기 전에 키가 먼저 만료돼
+	// deregistered 이벤트가 발행되지 않을 수 있고, unhealthyTimeout보다도 짧으면 unhealthy 이벤트도
+	// 발행되지 않는다.
+	minServiceTTL := deregisterTimeout + 2*sweepInterval
+	serviceTTL = getDurationEnv("SERVICE_TTL", minServiceTTL)
+	if serviceTTL <= deregisterTimeout {
+		log.Printf("SERVICE_TTL (%s) must exceed DEREGISTER_TIMEOUT (%s); using %s instead", serviceTTL, deregisterTimeout, minServiceTTL)
+		serviceTTL = minServiceTTL
+	}
+}
+
+// getDurationEnv는 환경 변수를 time.Duration으로 파싱하고, 없거나 잘못된 경우 기본값을 반환한다.
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s=%q, using default %s: %v", key, value, fallback, err)
+		return fallback
+	}
+	return d
 }
 
 // kafka consumer가 메시지 받기 시작
@@ -84,34 +135,48 @@ func registerService(reader *kafka.Reader) {
 			continue
 		}
 
-		var service lib.Service
-		err = json.Unmarshal(msg.Value, &service)
+		var entry registryEntry
+		err = json.Unmarshal(msg.Value, &entry)
 		if err != nil {
 			log.Printf("Error unmarshaling service data: %v", err)
 			continue
 		}
 
-		service.LastHeartbeat = time.Now()
+		entry.LastHeartbeat = time.Now()
+		entry.Status = resolveInitialStatus(entry.RequiredServices)
+		if entry.InstanceID == "" {
+			entry.InstanceID = computeInstanceID(entry.Service)
+		}
 
-		serviceJSON, err := json.Marshal(service)
+		entryJSON, err := json.Marshal(entry)
 		if err != nil {
 			log.Printf("Error marshaling service: %v", err)
 			continue
 		}
 
-		err = redisClient.Set(ctx, service.Name, serviceJSON, 0).Err()
+		err = redisClient.Set(ctx, instanceKey(entry.Name, entry.InstanceID), entryJSON, serviceTTL).Err()
 		if err != nil {
 			log.Printf("Error registering service: %v", err)
 			continue
 		}
 
-		err = redisClient.SAdd(ctx, "all:services", service.Name).Err()
+		err = redisClient.SAdd(ctx, instancesSetKey(entry.Name), entry.InstanceID).Err()
+		if err != nil {
+			log.Printf("Error adding instance to set: %v", err)
+			continue
+		}
+
+		err = redisClient.SAdd(ctx, "all:services", entry.Name).Err()
 		if err != nil {
 			log.Printf("Error adding service to set: %v", err)
 			continue
 		}
 
-		log.Printf("Service registered: %s", service.Name)
+		if entry.Status == StatusHealthy {
+			publishEvent("service.up", entry)
+		}
+
+		log.Printf("Service registered: %s (instance=%s, status=%s)", entry.Name, entry.InstanceID, entry.Status)
 	}
 }
 
@@ -119,38 +184,55 @@ func registerService(reader *kafka.Reader) {
 // 서비스 업데이트 함수는 지금은 이름을 변경하지 않는다는 전제가 있다.
 func updateService(c *gin.Context) {
 	name := c.Param("name")
+	instanceID := c.Param("id")
+	key := instanceKey(name, instanceID)
 
-	var service lib.Service
-	if err := c.ShouldBindJSON(&service); err != nil {
+	var payload struct {
+		lib.Service
+		RequiredServices []string `json:"required_services,omitempty"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := validateService(&service); err != nil {
+	if err := validateService(&payload.Service); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	var updated registryEntry
+
 	err := redisClient.Watch(ctx, func(tx *redis.Tx) error {
-		exists, err := redisClient.Exists(ctx, name).Result()
-		if err != nil {
+		existingJSON, err := tx.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return fmt.Errorf("service not found")
+		} else if err != nil {
 			return err
 		}
-		if exists == 0 {
-			return fmt.Errorf("service not found")
+
+		var existing registryEntry
+		if err := json.Unmarshal([]byte(existingJSON), &existing); err != nil {
+			return err
 		}
-		service.LastHeartbeat = time.Now()
 
-		serviceJSON, err := json.Marshal(service)
+		payload.LastHeartbeat = time.Now()
+		updated = registryEntry{Service: payload.Service, Status: existing.Status, RequiredServices: payload.RequiredServices, InstanceID: instanceID}
+
+		entryJSON, err := json.Marshal(updated)
 		if err != nil {
 			return err
 		}
 
 		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
-			return p.Set(ctx, name, serviceJSON, 0).Err()
+			return p.Set(ctx, key, entryJSON, serviceTTL).Err()
 		})
 		return err
-	}, name)
+	}, key)
+
+	if err == nil {
+		publishEvent("service.updated", updated)
+	}
 
 	if err != nil {
 		if err == redis.TxFailedErr {
@@ -169,117 +251,148 @@ func updateService(c *gin.Context) {
 // 서비스 제거 함수
 func deleteService(c *gin.Context) {
 	name := c.Param("name")
+	instanceID := c.Param("id")
+	key := instanceKey(name, instanceID)
 
-	exists, err := redisClient.Exists(ctx, name).Result()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check service existence"})
-		return
-	}
-	if exists == 0 {
+	entryJSON, err := redisClient.Get(ctx, key).Result()
+	if err == redis.Nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
 		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check service existence"})
+		return
 	}
 
-	err = redisClient.Del(ctx, name).Err()
+	err = redisClient.Del(ctx, key).Err()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete service"})
 		return
 	}
 
-	err = redisClient.SRem(ctx, "all:services", name).Err()
+	err = redisClient.SRem(ctx, instancesSetKey(name), instanceID).Err()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove service from set"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove instance from set"})
 		return
 	}
 
+	remaining, err := redisClient.SCard(ctx, instancesSetKey(name)).Result()
+	if err == nil && remaining == 0 {
+		redisClient.SRem(ctx, "all:services", name)
+	}
+
+	var entry registryEntry
+	if err := json.Unmarshal([]byte(entryJSON), &entry); err == nil {
+		entry.Status = StatusDeregistered
+		publishEvent("service.deregistered", entry)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Service deleted successfully"})
 }
 
 // 서비스 리스트 반환 함수
+// status 쿼리 파라미터(healthy|unhealthy)로 결과를 필터링할 수 있다.
 func listServices(c *gin.Context) {
+	statusFilter := c.Query("status")
+
 	serviceNames, err := redisClient.SMembers(ctx, "all:services").Result()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list services"})
 		return
 	}
 
-	services := make(map[string]lib.Service)
+	services := make(map[string][]registryEntry)
 	for _, name := range serviceNames {
-		serviceJSON, err := redisClient.Get(ctx, name).Result()
+		entries, err := getInstances(name)
 		if err != nil {
-			log.Printf("Failed to get service %s: %v", name, err)
+			log.Printf("Failed to get instances for %s: %v", name, err)
 			continue
 		}
 
-		var service lib.Service
-		err = json.Unmarshal([]byte(serviceJSON), &service)
-		if err != nil {
-			log.Printf("Failed to unmarshal service %s: %v", name, err)
+		if statusFilter != "" {
+			filtered := make([]registryEntry, 0, len(entries))
+			for _, entry := range entries {
+				if entry.Status == statusFilter {
+					filtered = append(filtered, entry)
+				}
+			}
+			entries = filtered
+		}
+
+		if len(entries) == 0 {
 			continue
 		}
-		services[name] = service
+
+		services[name] = entries
 	}
 
 	c.JSON(http.StatusOK, services)
 }
 
-// 서비스 반환 함수
+// 서비스 반환 함수 (이름에 속한 모든 인스턴스를 반환한다)
 func getService(c *gin.Context) {
 	name := c.Param("name")
 
-	serviceJSON, err := redisClient.Get(ctx, name).Result()
-	if err == redis.Nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
-		return
-	} else if err != nil {
+	entries, err := getInstances(name)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get service"})
 		return
 	}
-
-	var service lib.Service
-	err = json.Unmarshal([]byte(serviceJSON), &service)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal service"})
+	if len(entries) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, service)
+	c.JSON(http.StatusOK, entries)
 }
 
 // 헬스체크 함수
 func healthCheck(c *gin.Context) {
 	name := c.Param("name")
+	instanceID := c.Param("id")
+	key := instanceKey(name, instanceID)
+
+	var recovered *registryEntry
 
 	err := redisClient.Watch(ctx, func(tx *redis.Tx) error {
-		exists, err := tx.Exists(ctx, name).Result()
-		if err != nil {
-			return err
-		}
-		if exists == 0 {
+		entryJSON, err := tx.Get(ctx, key).Result()
+		if err == redis.Nil {
 			return fmt.Errorf("service not found")
+		} else if err != nil {
+			return err
 		}
 
-		serviceJSON, err := tx.Get(ctx, name).Result()
-		if err != nil {
+		var entry registryEntry
+		if err := json.Unmarshal([]byte(entryJSON), &entry); err != nil {
 			return err
 		}
 
-		var service lib.Service
-		if err := json.Unmarshal([]byte(serviceJSON), &service); err != nil {
-			return err
+		entry.LastHeartbeat = time.Now()
+		switch entry.Status {
+		case StatusUnhealthy:
+			entry.Status = StatusHealthy
+			recovered = &entry
+		case StatusPending:
+			// pending은 하트비트만으로 승격시키지 않는다 - 의존 서비스가 실제로 떠 있어야 한다.
+			if dependenciesReady(entry.RequiredServices) {
+				entry.Status = StatusHealthy
+				recovered = &entry
+			}
 		}
 
-		service.LastHeartbeat = time.Now()
-		updatedServiceJSON, err := json.Marshal(service)
+		updatedEntryJSON, err := json.Marshal(entry)
 		if err != nil {
 			return err
 		}
 
 		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
-			return p.Set(ctx, name, updatedServiceJSON, 0).Err()
+			return p.Set(ctx, key, updatedEntryJSON, serviceTTL).Err()
 		})
 		return err
-	}, name)
+	}, key)
+
+	if err == nil && recovered != nil {
+		publishEvent("service.up", *recovered)
+	}
 
 	if err != nil {
 		if err == redis.TxFailedErr {
@@ -301,17 +414,29 @@ func healthHandler(c *gin.Context) {
 
 func main() {
 	go startKafkaConsumer()
+	go startSweeper()
+	go startRegistryEventsConsumer()
 
 	router := gin.Default()
+	router.Use(quotaMiddleware)
+	// /admin/quotas/:route의 라우트 키는 %2F로 인코딩된 "/"를 담으므로, 디코딩 전 경로로 매칭한다.
+	router.UseRawPath = true
 
 	router.GET("/services", listServices)
+	router.GET("/services/watch", watchServices)
 	router.GET("/services/:name", getService)
-	router.PUT("/services/:name", updateService)
-	router.DELETE("/services/:name", deleteService)
-	router.POST("/health/:name", healthCheck)
+	router.GET("/services/:name/instance", getServiceInstance)
+	router.GET("/services/:name/dependencies", getServiceDependencies)
+	router.GET("/services/:name/ready", getServiceReadiness)
+	router.PUT("/services/:name/:id", updateService)
+	router.DELETE("/services/:name/:id", deleteService)
+	router.POST("/health/:name/:id", healthCheck)
 	router.GET("/health", healthHandler)
 	router.HEAD("/health", healthHandler)
 
+	router.GET("/admin/quotas", getQuotas)
+	router.PUT("/admin/quotas/:route", updateQuota)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8500"
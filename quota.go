@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// quotaConfig는 라우트별 토큰 버킷 설정(버킷 크기, 초당 충전량)을 나타낸다.
+type quotaConfig struct {
+	Capacity        int     `json:"capacity"`
+	RefillPerSecond float64 `json:"refill_per_second"`
+}
+
+var (
+	quotaMu      sync.RWMutex
+	routeQuotas  map[string]quotaConfig
+	defaultQuota = quotaConfig{Capacity: 20, RefillPerSecond: 1}
+)
+
+// initQuotas는 라우트별 기본 쿼터를 설정한다.
+// 하트비트처럼 자주 호출되는 라우트는 버스트를 크게, 변경 계열 라우트는 작게 잡는다.
+func initQuotas() {
+	routeQuotas = map[string]quotaConfig{
+		"POST /health/:name/:id":     {Capacity: 120, RefillPerSecond: 5},
+		"PUT /services/:name/:id":    {Capacity: 5, RefillPerSecond: 0.2},
+		"DELETE /services/:name/:id": {Capacity: 5, RefillPerSecond: 0.2},
+	}
+}
+
+func quotaFor(routeKey string) quotaConfig {
+	quotaMu.RLock()
+	defer quotaMu.RUnlock()
+
+	if q, ok := routeQuotas[routeKey]; ok {
+		return q
+	}
+	return defaultQuota
+}
+
+func setQuotaFor(routeKey string, q quotaConfig) {
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+
+	routeQuotas[routeKey] = q
+}
+
+// tokenBucketScript는 토큰 버킷의 충전과 소비를 원자적으로 수행하는 Lua 스크립트.
+// 반환값은 {허용 여부(1/0), 남은 토큰, 거부 시 대기해야 할 밀리초}.
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local capacity = tonumber(ARGV[1])
+local refill_per_ms = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local requested = tonumber(ARGV[4])
+
+local data = redis.call("HMGET", key, "tokens", "timestamp")
+local tokens = tonumber(data[1])
+local timestamp = tonumber(data[2])
+
+if tokens == nil then
+	tokens = capacity
+	timestamp = now
+end
+
+local elapsed = math.max(0, now - timestamp)
+tokens = math.min(capacity, tokens + elapsed * refill_per_ms)
+
+local allowed = 0
+local wait = 0
+if tokens >= requested then
+	tokens = tokens - requested
+	allowed = 1
+else
+	local deficit = requested - tokens
+	wait = math.ceil(deficit / refill_per_ms)
+end
+
+redis.call("HMSET", key, "tokens", tokens, "timestamp", now)
+redis.call("PEXPIRE", key, math.ceil(capacity / refill_per_ms) + 1000)
+
+return {allowed, tokens, wait}
+`)
+
+// takeToken은 주어진 버킷에서 토큰 하나를 소비하려 시도한다.
+func takeToken(bucketKey string, q quotaConfig) (allowed bool, waitMillis int64, err error) {
+	refillPerMs := q.RefillPerSecond / 1000
+	now := time.Now().UnixMilli()
+
+	result, err := tokenBucketScript.Run(ctx, redisClient, []string{bucketKey}, q.Capacity, refillPerMs, now, 1).Result()
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 3 {
+		return false, 0, fmt.Errorf("unexpected token bucket result: %v", result)
+	}
+
+	allowedInt, _ := values[0].(int64)
+	waitInt, _ := values[2].(int64)
+
+	return allowedInt == 1, waitInt, nil
+}
+
+// quotaMiddleware는 클라이언트 IP와 (있는 경우) 서비스 이름 기준으로 토큰 버킷 제한을 적용한다.
+// 버킷은 Redis에 저장되므로 레지스트리 레플리카 간에 제한이 공유된다.
+func quotaMiddleware(c *gin.Context) {
+	routeKey := c.Request.Method + " " + c.FullPath()
+	q := quotaFor(routeKey)
+
+	var waitMillis int64
+
+	ipAllowed, ipWait, err := takeToken(fmt.Sprintf("quota:ip:%s:%s", c.ClientIP(), routeKey), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate rate limit"})
+		c.Abort()
+		return
+	}
+	if !ipAllowed {
+		waitMillis = ipWait
+	}
+
+	if name := c.Param("name"); name != "" {
+		nameAllowed, nameWait, err := takeToken(fmt.Sprintf("quota:service:%s:%s", name, routeKey), q)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate rate limit"})
+			c.Abort()
+			return
+		}
+		if !nameAllowed && nameWait > waitMillis {
+			waitMillis = nameWait
+		}
+	}
+
+	if waitMillis > 0 {
+		retryAfterSeconds := int64(math.Ceil(float64(waitMillis) / 1000))
+		if retryAfterSeconds < 1 {
+			retryAfterSeconds = 1
+		}
+		c.Header("Retry-After", strconv.FormatInt(retryAfterSeconds, 10))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded", "wait_millis": waitMillis})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// getQuotas 함수: GET /admin/quotas
+func getQuotas(c *gin.Context) {
+	quotaMu.RLock()
+	defer quotaMu.RUnlock()
+
+	routes := make(map[string]quotaConfig, len(routeQuotas))
+	for k, v := range routeQuotas {
+		routes[k] = v
+	}
+
+	c.JSON(http.StatusOK, gin.H{"default": defaultQuota, "routes": routes})
+}
+
+// updateQuota 함수: PUT /admin/quotas/:route
+// :route는 "METHOD /path" 형식의 라우트 키를 URL 인코딩한 값이다 (예: "PUT%20%2Fservices%2F%3Aname%2F%3Aid").
+func updateQuota(c *gin.Context) {
+	routeKey := c.Param("route")
+
+	var q quotaConfig
+	if err := c.ShouldBindJSON(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if q.Capacity <= 0 || q.RefillPerSecond <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "capacity and refill_per_second must be positive"})
+		return
+	}
+
+	setQuotaFor(routeKey, q)
+	c.JSON(http.StatusOK, gin.H{"route": routeKey, "quota": q})
+}
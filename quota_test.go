@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+)
+
+// takeToken은 원자적 충전/소비를 Redis Lua 스크립트로 수행하므로, 이 테스트는 init()이
+// 연결해 둔 redisClient를 그대로 사용하는 통합 테스트다. 테스트마다 고유한 버킷 키를 쓰고
+// 끝나면 지워서 서로 간섭하지 않게 한다.
+func TestTakeToken_AllowsWithinCapacity(t *testing.T) {
+	key := "test:quota:" + t.Name()
+	t.Cleanup(func() { redisClient.Del(ctx, key) })
+
+	q := quotaConfig{Capacity: 2, RefillPerSecond: 1}
+
+	allowed, _, err := takeToken(key, q)
+	if err != nil {
+		t.Fatalf("takeToken returned error: %v", err)
+	}
+	if !allowed {
+		t.Errorf("expected first request within capacity to be allowed")
+	}
+}
+
+func TestTakeToken_BlocksWhenExhausted(t *testing.T) {
+	key := "test:quota:" + t.Name()
+	t.Cleanup(func() { redisClient.Del(ctx, key) })
+
+	q := quotaConfig{Capacity: 1, RefillPerSecond: 0.001}
+
+	allowed, _, err := takeToken(key, q)
+	if err != nil {
+		t.Fatalf("takeToken returned error: %v", err)
+	}
+	if !allowed {
+		t.Fatalf("expected the first request to consume the only token")
+	}
+
+	allowed, waitMillis, err := takeToken(key, q)
+	if err != nil {
+		t.Fatalf("takeToken returned error: %v", err)
+	}
+	if allowed {
+		t.Errorf("expected the bucket to be exhausted after the first request")
+	}
+	if waitMillis <= 0 {
+		t.Errorf("expected a positive wait when the bucket is exhausted, got %d", waitMillis)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+)
+
+var (
+	sweepInterval     time.Duration
+	unhealthyTimeout  time.Duration
+	deregisterTimeout time.Duration
+	eventWriter       *kafka.Writer
+)
+
+// initSweeper는 스위퍼 관련 설정값을 환경 변수로부터 읽고 이벤트 writer를 준비한다.
+func initSweeper() {
+	sweepInterval = getDurationEnv("SWEEP_INTERVAL", 10*time.Second)
+	unhealthyTimeout = getDurationEnv("UNHEALTHY_TIMEOUT", 30*time.Second)
+	deregisterTimeout = getDurationEnv("DEREGISTER_TIMEOUT", 2*time.Minute)
+
+	eventWriter = &kafka.Writer{
+		Addr:     kafka.TCP(kafkaBroker),
+		Topic:    eventsTopic,
+		Balancer: &kafka.LeastBytes{},
+	}
+}
+
+// registryEvent는 watch 허브로 전달되고 Kafka registry-events 토픽에도 발행되는 이벤트의 형태.
+// Origin은 이벤트를 발행한 인스턴스를 식별해, Kafka를 거쳐 되돌아온 이벤트가 로컬 허브에
+// 중복 전달되지 않도록 하는 데 쓰인다.
+type registryEvent struct {
+	Type    string        `json:"type"`
+	Service registryEntry `json:"service"`
+	Origin  string        `json:"origin,omitempty"`
+}
+
+// publishEvent는 서비스 상태 변화를 이 인스턴스의 watch 허브에 즉시 브로드캐스트하고,
+// 다른 레플리카의 허브와 동기화할 수 있도록 Kafka 이벤트 토픽에도 비동기로 발행한다.
+func publishEvent(eventType string, entry registryEntry) {
+	event := registryEvent{Type: eventType, Service: entry, Origin: localOriginID}
+
+	eventHub.broadcast(event)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error marshaling event %s for %s: %v", eventType, entry.Name, err)
+		return
+	}
+
+	go func() {
+		err := eventWriter.WriteMessages(context.Background(), kafka.Message{
+			Key:   []byte(entry.Name),
+			Value: payload,
+		})
+		if err != nil {
+			log.Printf("Error publishing event %s for %s: %v", eventType, entry.Name, err)
+		}
+	}()
+}
+
+// startSweeper는 all:services를 주기적으로 스캔해 하트비트가 끊긴 서비스를
+// unhealthy로 표시하고, 더 오래 방치된 서비스는 완전히 제거한다.
+func startSweeper() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sweepOnce()
+	}
+}
+
+func sweepOnce() {
+	serviceNames, err := redisClient.SMembers(ctx, "all:services").Result()
+	if err != nil {
+		log.Printf("Sweeper: failed to list services: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, name := range serviceNames {
+		instanceIDs, err := redisClient.SMembers(ctx, instancesSetKey(name)).Result()
+		if err != nil {
+			log.Printf("Sweeper: failed to list instances for %s: %v", name, err)
+			continue
+		}
+
+		for _, instanceID := range instanceIDs {
+			sweepInstance(name, instanceID, now)
+		}
+
+		remaining, err := redisClient.SCard(ctx, instancesSetKey(name)).Result()
+		if err == nil && remaining == 0 {
+			redisClient.SRem(ctx, "all:services", name)
+		}
+	}
+}
+
+func sweepInstance(name, instanceID string, now time.Time) {
+	key := instanceKey(name, instanceID)
+
+	entryJSON, err := redisClient.Get(ctx, key).Result()
+	if err == redis.Nil {
+		// 키가 이미 TTL로 만료된 경우: 인스턴스 세트에서만 제거.
+		redisClient.SRem(ctx, instancesSetKey(name), instanceID)
+		return
+	} else if err != nil {
+		log.Printf("Sweeper: failed to get instance %s: %v", key, err)
+		return
+	}
+
+	var entry registryEntry
+	if err := json.Unmarshal([]byte(entryJSON), &entry); err != nil {
+		log.Printf("Sweeper: failed to unmarshal instance %s: %v", key, err)
+		return
+	}
+
+	age := now.Sub(entry.LastHeartbeat)
+
+	if age >= deregisterTimeout {
+		redisClient.Del(ctx, key)
+		redisClient.SRem(ctx, instancesSetKey(name), instanceID)
+		entry.Status = StatusDeregistered
+		publishEvent("service.deregistered", entry)
+		log.Printf("Sweeper: deregistered stale instance %s (age %s)", key, age)
+		return
+	}
+
+	if age >= unhealthyTimeout && entry.Status == StatusHealthy {
+		entry.Status = StatusUnhealthy
+		entryJSON, err := json.Marshal(entry)
+		if err != nil {
+			log.Printf("Sweeper: failed to marshal instance %s: %v", key, err)
+			return
+		}
+		if err := redisClient.Set(ctx, key, entryJSON, serviceTTL).Err(); err != nil {
+			log.Printf("Sweeper: failed to mark %s unhealthy: %v", key, err)
+			return
+		}
+		publishEvent("service.down", entry)
+		log.Printf("Sweeper: marked %s unhealthy (age %s)", key, age)
+		return
+	}
+
+	if entry.Status == StatusPending && dependenciesReady(entry.RequiredServices) {
+		entry.Status = StatusHealthy
+		entryJSON, err := json.Marshal(entry)
+		if err != nil {
+			log.Printf("Sweeper: failed to marshal instance %s: %v", key, err)
+			return
+		}
+		if err := redisClient.Set(ctx, key, entryJSON, serviceTTL).Err(); err != nil {
+			log.Printf("Sweeper: failed to promote %s: %v", key, err)
+			return
+		}
+		publishEvent("service.up", entry)
+		log.Printf("Sweeper: promoted %s to healthy (dependencies ready)", key)
+	}
+}
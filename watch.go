@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+)
+
+// registryHub은 레지스트리 변경 이벤트를 프로세스 내 watch 구독자들에게 팬아웃하는 허브.
+type registryHub struct {
+	mu          sync.Mutex
+	subscribers map[chan registryEvent]struct{}
+}
+
+func newRegistryHub() *registryHub {
+	return &registryHub{subscribers: make(map[chan registryEvent]struct{})}
+}
+
+func (h *registryHub) subscribe() chan registryEvent {
+	ch := make(chan registryEvent, 16)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch
+}
+
+func (h *registryHub) unsubscribe(ch chan registryEvent) {
+	h.mu.Lock()
+	delete(h.subscribers, ch)
+	h.mu.Unlock()
+
+	close(ch)
+}
+
+func (h *registryHub) broadcast(event registryEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// 구독자가 느려서 버퍼가 찬 경우 이벤트를 건너뛴다.
+			log.Printf("Watch: dropping event %s for slow subscriber", event.Type)
+		}
+	}
+}
+
+var eventHub = newRegistryHub()
+
+// localOriginID는 이 인스턴스를 식별하는 값으로, publishEvent가 직접 쓰는 로컬 허브 브로드캐스트와
+// Kafka를 거쳐 되돌아오는 동일 이벤트를 구분해 중복 전달을 막는 데 쓰인다.
+var localOriginID = hostnameOrRandom()
+
+// startRegistryEventsConsumer는 registry-events 토픽을 구독해 다른 인스턴스가 발행한 이벤트를
+// 이 인스턴스의 허브로 중계한다. publishEvent가 이미 로컬 허브에 직접 브로드캐스트하므로,
+// 이 인스턴스가 발행한 이벤트가 Kafka를 거쳐 되돌아온 경우는 건너뛴다.
+func startRegistryEventsConsumer() {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{kafkaBroker},
+		Topic:   eventsTopic,
+		GroupID: "registry-watch-" + localOriginID,
+	})
+	defer reader.Close()
+
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			log.Printf("Watch: error reading registry event: %v", err)
+			continue
+		}
+
+		var event registryEvent
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			log.Printf("Watch: error unmarshaling registry event: %v", err)
+			continue
+		}
+
+		if event.Origin == localOriginID {
+			continue
+		}
+
+		eventHub.broadcast(event)
+	}
+}
+
+// matchesWatchFilter는 이벤트가 name prefix/type 필터에 부합하는지 확인한다.
+func matchesWatchFilter(event registryEvent, namePrefix string, types map[string]bool) bool {
+	if namePrefix != "" && !strings.HasPrefix(event.Service.Name, namePrefix) {
+		return false
+	}
+	if len(types) > 0 && !types[event.Type] {
+		return false
+	}
+	return true
+}
+
+// watchServices 함수: GET /services/watch
+// 등록/수정/삭제/헬스 변화 이벤트를 SSE로 스트리밍한다. 최초 이벤트는 현재 상태의 스냅샷이다.
+// 등록 이벤트는 healthy로 등록될 때의 service.up뿐이며, pending으로 등록된 인스턴스는 등록 시점에
+// 이벤트가 나가지 않고 healthy로 승격될 때 service.up으로 처음 전달된다.
+// query: prefix(서비스 이름 접두사), type(콤마로 구분된 이벤트 타입 목록)
+func watchServices(c *gin.Context) {
+	namePrefix := c.Query("prefix")
+
+	types := make(map[string]bool)
+	if typeParam := c.Query("type"); typeParam != "" {
+		for _, t := range strings.Split(typeParam, ",") {
+			types[strings.TrimSpace(t)] = true
+		}
+	}
+
+	ch := eventHub.subscribe()
+	defer eventHub.unsubscribe(ch)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	snapshot, err := currentSnapshot(namePrefix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build snapshot"})
+		return
+	}
+	writeSSE(c, "snapshot", snapshot)
+
+	c.Stream(func(w io.Writer) bool {
+		event, ok := <-ch
+		if !ok {
+			return false
+		}
+		if !matchesWatchFilter(event, namePrefix, types) {
+			return true
+		}
+		writeSSE(c, event.Type, event)
+		return true
+	})
+}
+
+func writeSSE(c *gin.Context, event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Watch: failed to marshal SSE payload: %v", err)
+		return
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, data)
+	c.Writer.Flush()
+}
+
+// hostnameOrRandom은 인스턴스별 고유한 Kafka consumer group ID를 만들기 위한 접미사를 반환한다.
+func hostnameOrRandom() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// currentSnapshot은 접두사에 맞는 현재 등록된 서비스들을 모아 반환한다.
+func currentSnapshot(namePrefix string) ([]registryEntry, error) {
+	serviceNames, err := redisClient.SMembers(ctx, "all:services").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]registryEntry, 0, len(serviceNames))
+	for _, name := range serviceNames {
+		if namePrefix != "" && !strings.HasPrefix(name, namePrefix) {
+			continue
+		}
+
+		instances, err := getInstances(name)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, instances...)
+	}
+
+	return entries, nil
+}